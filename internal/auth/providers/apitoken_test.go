@@ -0,0 +1,81 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-shiori/shiori/internal/model"
+)
+
+// fakeTokenStore is a TokenStore backed by an in-memory map.
+type fakeTokenStore struct {
+	byHash map[string]model.Account
+}
+
+func (s *fakeTokenStore) GetAccountByTokenHash(ctx context.Context, tokenHash string) (model.Account, bool, error) {
+	account, found := s.byHash[tokenHash]
+	return account, found, nil
+}
+
+func (s *fakeTokenStore) CreateToken(ctx context.Context, accountID int, name string) (string, error) {
+	token, err := GenerateAPIToken()
+	if err != nil {
+		return "", err
+	}
+	if s.byHash == nil {
+		s.byHash = map[string]model.Account{}
+	}
+	s.byHash[HashAPIToken(token)] = model.Account{ID: accountID}
+	return token, nil
+}
+
+func (s *fakeTokenStore) RevokeToken(ctx context.Context, accountID int, name string) error {
+	for hash, account := range s.byHash {
+		if account.ID == accountID {
+			delete(s.byHash, hash)
+		}
+	}
+	return nil
+}
+
+func TestAPITokenProviderAuthenticatesValidToken(t *testing.T) {
+	store := &fakeTokenStore{}
+	token, err := store.CreateToken(context.Background(), 7, "ci")
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	p := NewAPITokenProvider(store)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(model.AuthorizationHeader, "Token "+token)
+
+	account, err := p.Authenticate(r)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v, want nil", err)
+	}
+	if account.ID != 7 {
+		t.Errorf("Authenticate() account.ID = %d, want 7", account.ID)
+	}
+}
+
+func TestAPITokenProviderRejectsUnknownToken(t *testing.T) {
+	p := NewAPITokenProvider(&fakeTokenStore{})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(model.AuthorizationHeader, "Token does-not-exist")
+
+	if account, err := p.Authenticate(r); err == nil {
+		t.Fatalf("Authenticate() = %+v, want error for unknown token", account)
+	}
+}
+
+func TestAPITokenProviderRejectsWrongScheme(t *testing.T) {
+	p := NewAPITokenProvider(&fakeTokenStore{})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(model.AuthorizationHeader, "Bearer something")
+
+	if account, err := p.Authenticate(r); err == nil {
+		t.Fatalf("Authenticate() = %+v, want error for non-Token scheme", account)
+	}
+}
@@ -0,0 +1,44 @@
+package providers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-shiori/shiori/internal/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicAuthProvider authenticates requests using HTTP Basic auth, mainly
+// intended for scripts and cron jobs that can't go through the login page
+// or an OAuth flow.
+type BasicAuthProvider struct {
+	accounts accountStore
+}
+
+// NewBasicAuthProvider creates a provider backed by accounts.
+func NewBasicAuthProvider(accounts accountStore) *BasicAuthProvider {
+	return &BasicAuthProvider{accounts: accounts}
+}
+
+// Name returns "basic".
+func (p *BasicAuthProvider) Name() string { return "basic" }
+
+// Authenticate verifies the request's Basic auth credentials against the
+// accounts database.
+func (p *BasicAuthProvider) Authenticate(r *http.Request) (model.Account, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return model.Account{}, fmt.Errorf("未提供 Basic 认证信息")
+	}
+
+	account, found, err := p.accounts.GetAccount(r.Context(), username)
+	if err != nil || !found {
+		return model.Account{}, fmt.Errorf("用户名或密码不正确")
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(password)) != nil {
+		return model.Account{}, fmt.Errorf("用户名或密码不正确")
+	}
+
+	return account, nil
+}
@@ -0,0 +1,64 @@
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-shiori/shiori/internal/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBasicAuthProviderAuthenticatesValidCredentials(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("bcrypt.GenerateFromPassword() error = %v", err)
+	}
+	accounts := &fakeAccountStore{
+		byUsername: map[string]model.Account{
+			"alice": {ID: 1, Username: "alice", Password: string(hash)},
+		},
+	}
+
+	p := NewBasicAuthProvider(accounts)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.SetBasicAuth("alice", "hunter2")
+
+	account, err := p.Authenticate(r)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v, want nil", err)
+	}
+	if account.Username != "alice" {
+		t.Errorf("Authenticate() = %q, want %q", account.Username, "alice")
+	}
+}
+
+func TestBasicAuthProviderRejectsWrongPassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("bcrypt.GenerateFromPassword() error = %v", err)
+	}
+	accounts := &fakeAccountStore{
+		byUsername: map[string]model.Account{
+			"alice": {ID: 1, Username: "alice", Password: string(hash)},
+		},
+	}
+
+	p := NewBasicAuthProvider(accounts)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.SetBasicAuth("alice", "wrong-password")
+
+	if account, err := p.Authenticate(r); err == nil {
+		t.Fatalf("Authenticate() = %+v, want error for wrong password", account)
+	}
+}
+
+func TestBasicAuthProviderRejectsUnknownAccount(t *testing.T) {
+	p := NewBasicAuthProvider(&fakeAccountStore{})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.SetBasicAuth("ghost", "whatever")
+
+	if account, err := p.Authenticate(r); err == nil {
+		t.Fatalf("Authenticate() = %+v, want error for unknown account", account)
+	}
+}
@@ -0,0 +1,270 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-shiori/shiori/internal/model"
+	"golang.org/x/oauth2"
+)
+
+// OAuthConfig holds the client credentials and endpoints for a single
+// OAuth2/OIDC provider (Google, GitHub, or any other generic provider that
+// speaks the standard authorization-code flow).
+type OAuthConfig struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Endpoint     oauth2.Endpoint
+	Scopes       []string
+
+	// UserInfoURL is queried with the obtained access token to resolve the
+	// remote account, used to auto-provision local accounts on first login.
+	UserInfoURL string
+
+	// ExternalIDClaims lists the userinfo response fields to try, in order,
+	// for a stable per-account identifier to key the (provider, external
+	// ID) identity mapping on -- e.g. the OIDC "sub" claim, or GitHub's
+	// numeric "id". This is deliberately never the username/email claim:
+	// a generic OIDC provider's userinfo response is attacker-influenced
+	// input (a self-hosted IdP lets its own users set "preferred_username"
+	// or "email"), so matching accounts on it would let a remote user sign
+	// in as any existing local account just by presenting the same claim.
+	// Defaults to defaultExternalIDClaims.
+	ExternalIDClaims []string
+
+	// UsernameClaims lists the userinfo response fields to try, in order,
+	// when naming a *new* account the first time a given external ID is
+	// seen -- different providers shape their userinfo response
+	// differently (GitHub returns "login", Google and most generic OIDC
+	// providers don't). The first claim with a non-empty string value
+	// wins, except "email", which is only used if the response also marks
+	// it "email_verified" -- an unverified email claim is exactly the kind
+	// of attacker-influenced input this provider must not trust blindly.
+	// Defaults to defaultUsernameClaims.
+	UsernameClaims []string
+
+	// AutoProvision creates a local account the first time a remote user
+	// authenticates successfully, instead of requiring it to already exist.
+	AutoProvision bool
+}
+
+// defaultExternalIDClaims covers the OIDC "sub" claim and GitHub's numeric
+// "id", the two stable identifiers this package knows how to read from a
+// userinfo response.
+var defaultExternalIDClaims = []string{"sub", "id"}
+
+// defaultUsernameClaims is used when a config doesn't set UsernameClaims. It
+// covers GitHub ("login") and the claims a standard OIDC userinfo endpoint
+// is expected to populate ("preferred_username", "email").
+var defaultUsernameClaims = []string{"preferred_username", "email", "login"}
+
+// accountStore is the subset of database.DB that OAuthProvider needs for
+// account lookups and auto-provisioning, narrowed down the same way
+// bookmarkCounter narrows database.DB in webserver/observability.go -- so
+// Exchange's account-resolution logic can be exercised against a fake
+// instead of the full interface.
+type accountStore interface {
+	GetAccount(ctx context.Context, username string) (model.Account, bool, error)
+	SaveAccount(ctx context.Context, account model.Account) error
+}
+
+// identityStore persists the stable (provider, external_id) -> account_id
+// mapping Exchange resolves logins against. See SQLIdentityStore for the
+// table-backed implementation.
+type identityStore interface {
+	GetAccountByExternalID(ctx context.Context, provider, externalID string) (model.Account, bool, error)
+	LinkExternalID(ctx context.Context, accountID int, provider, externalID string) error
+}
+
+// OAuthProvider drives the `/oauth/{name}/login` and `/oauth/{name}/callback`
+// flow for one OAuthConfig. It does not authenticate raw requests -- by the
+// time a request reaches the auth middleware the OAuth dance has already
+// completed and a regular Shiori session cookie has been issued, so
+// Authenticate always fails. The provider is still registered so it can be
+// looked up by name from the registry.
+type OAuthProvider struct {
+	cfg        OAuthConfig
+	oauth      *oauth2.Config
+	accounts   accountStore
+	identities identityStore
+}
+
+// NewOAuthProvider creates a provider for one OAuth2/OIDC config entry,
+// backed by accounts for account lookups/creation and identities for the
+// external-identity mapping Exchange resolves logins against.
+func NewOAuthProvider(accounts accountStore, identities identityStore, cfg OAuthConfig) *OAuthProvider {
+	return &OAuthProvider{
+		cfg: cfg,
+		oauth: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     cfg.Endpoint,
+			Scopes:       cfg.Scopes,
+		},
+		accounts:   accounts,
+		identities: identities,
+	}
+}
+
+// Name returns the provider identifier, e.g. "google" or "github".
+func (p *OAuthProvider) Name() string { return p.cfg.Name }
+
+// Authenticate is a no-op for OAuth providers; see the type doc comment.
+func (p *OAuthProvider) Authenticate(r *http.Request) (model.Account, error) {
+	return model.Account{}, fmt.Errorf("OAuth 提供方 %q 不直接认证 API 请求", p.cfg.Name)
+}
+
+// AuthCodeURL returns the provider's authorization URL for the given CSRF
+// state value.
+func (p *OAuthProvider) AuthCodeURL(state string) string {
+	return p.oauth.AuthCodeURL(state)
+}
+
+// Exchange trades the authorization code received on the callback for a
+// token and resolves it to a local account via the stable (provider,
+// external_id) identity recorded in p.identities, auto-provisioning an
+// account (and linking its identity) the first time AutoProvision is set
+// and no matching identity exists yet.
+func (p *OAuthProvider) Exchange(ctx context.Context, code string) (model.Account, error) {
+	token, err := p.oauth.Exchange(ctx, code)
+	if err != nil {
+		return model.Account{}, fmt.Errorf("交换 OAuth 令牌失败: %v", err)
+	}
+
+	return p.resolveAccount(ctx, token)
+}
+
+// resolveAccount does the claim-fetching and account-resolution half of
+// Exchange, split out so it can be driven directly against a stubbed token
+// in tests without needing a live token endpoint.
+func (p *OAuthProvider) resolveAccount(ctx context.Context, token *oauth2.Token) (model.Account, error) {
+	claims, err := fetchOAuthClaims(ctx, p.cfg.UserInfoURL, token)
+	if err != nil {
+		return model.Account{}, err
+	}
+
+	externalID, err := externalIDFromClaims(claims, p.externalIDClaims())
+	if err != nil {
+		return model.Account{}, err
+	}
+
+	if account, found, err := p.identities.GetAccountByExternalID(ctx, p.cfg.Name, externalID); err == nil && found {
+		return account, nil
+	}
+
+	if !p.cfg.AutoProvision {
+		return model.Account{}, fmt.Errorf("账户未绑定且未开启自动注册")
+	}
+
+	username, err := usernameFromClaims(claims, p.usernameClaims())
+	if err != nil {
+		return model.Account{}, err
+	}
+
+	// usernameFromClaims may have picked an IdP-controlled claim
+	// (preferred_username carries no verification requirement at all).
+	// Refuse to auto-provision over an existing account rather than
+	// silently taking it over -- the same reasoning as resolving logins by
+	// external ID instead of by username/email in the first place.
+	if _, found, err := p.accounts.GetAccount(ctx, username); err == nil && found {
+		return model.Account{}, fmt.Errorf("账户 %q 已存在，无法自动创建同名账户", username)
+	}
+
+	if err := p.accounts.SaveAccount(ctx, model.Account{Username: username}); err != nil {
+		return model.Account{}, fmt.Errorf("自动创建账户失败: %v", err)
+	}
+
+	account, found, err := p.accounts.GetAccount(ctx, username)
+	if err != nil || !found {
+		return model.Account{}, fmt.Errorf("读取新建账户失败: %v", err)
+	}
+
+	if err := p.identities.LinkExternalID(ctx, account.ID, p.cfg.Name, externalID); err != nil {
+		return model.Account{}, fmt.Errorf("绑定外部身份失败: %v", err)
+	}
+
+	return account, nil
+}
+
+// externalIDClaims returns p.cfg.ExternalIDClaims, falling back to
+// defaultExternalIDClaims if the config didn't set any.
+func (p *OAuthProvider) externalIDClaims() []string {
+	if len(p.cfg.ExternalIDClaims) > 0 {
+		return p.cfg.ExternalIDClaims
+	}
+	return defaultExternalIDClaims
+}
+
+// usernameClaims returns p.cfg.UsernameClaims, falling back to
+// defaultUsernameClaims if the config didn't set any.
+func (p *OAuthProvider) usernameClaims() []string {
+	if len(p.cfg.UsernameClaims) > 0 {
+		return p.cfg.UsernameClaims
+	}
+	return defaultUsernameClaims
+}
+
+// fetchOAuthClaims queries userInfoURL with token and returns the decoded
+// userinfo response as a claim map.
+func fetchOAuthClaims(ctx context.Context, userInfoURL string, token *oauth2.Token) (map[string]interface{}, error) {
+	client := oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
+
+	resp, err := client.Get(userInfoURL)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户信息失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var claims map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("解析用户信息失败: %v", err)
+	}
+
+	return claims, nil
+}
+
+// externalIDFromClaims resolves the stable per-account identifier from the
+// first of claimOrder present in claims, accepting either a string (the
+// OIDC "sub" claim) or a number (GitHub's numeric "id").
+func externalIDFromClaims(claims map[string]interface{}, claimOrder []string) (string, error) {
+	for _, claim := range claimOrder {
+		switch value := claims[claim].(type) {
+		case string:
+			if value != "" {
+				return value, nil
+			}
+		case float64:
+			return strconv.FormatInt(int64(value), 10), nil
+		}
+	}
+
+	return "", fmt.Errorf("用户信息中缺少稳定的外部身份标识")
+}
+
+// usernameFromClaims resolves the username to give a newly auto-provisioned
+// account from the first of claimOrder present in claims. An "email" claim
+// is only accepted if the response also marks it "email_verified" -- an
+// IdP that lets end users set an arbitrary, unverified email shouldn't be
+// able to name accounts after it.
+func usernameFromClaims(claims map[string]interface{}, claimOrder []string) (string, error) {
+	for _, claim := range claimOrder {
+		username, ok := claims[claim].(string)
+		if !ok || username == "" {
+			continue
+		}
+		if claim == "email" {
+			if verified, ok := claims["email_verified"].(bool); !ok || !verified {
+				continue
+			}
+		}
+		return username, nil
+	}
+
+	return "", fmt.Errorf("用户信息中缺少用户名")
+}
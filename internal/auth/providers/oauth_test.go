@@ -0,0 +1,189 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-shiori/shiori/internal/model"
+	"golang.org/x/oauth2"
+)
+
+// fakeAccountStore is an accountStore backed by an in-memory map.
+type fakeAccountStore struct {
+	byUsername map[string]model.Account
+	nextID     int
+}
+
+func (s *fakeAccountStore) GetAccount(ctx context.Context, username string) (model.Account, bool, error) {
+	account, found := s.byUsername[username]
+	return account, found, nil
+}
+
+func (s *fakeAccountStore) SaveAccount(ctx context.Context, account model.Account) error {
+	if s.byUsername == nil {
+		s.byUsername = map[string]model.Account{}
+	}
+	s.nextID++
+	account.ID = s.nextID
+	s.byUsername[account.Username] = account
+	return nil
+}
+
+// fakeIdentityStore is an identityStore backed by an in-memory map.
+type fakeIdentityStore struct {
+	accountsByIdentity map[string]model.Account
+	linked             []string
+}
+
+func identityKey(provider, externalID string) string { return provider + ":" + externalID }
+
+func (s *fakeIdentityStore) GetAccountByExternalID(ctx context.Context, provider, externalID string) (model.Account, bool, error) {
+	account, found := s.accountsByIdentity[identityKey(provider, externalID)]
+	return account, found, nil
+}
+
+func (s *fakeIdentityStore) LinkExternalID(ctx context.Context, accountID int, provider, externalID string) error {
+	s.linked = append(s.linked, identityKey(provider, externalID))
+	if s.accountsByIdentity == nil {
+		s.accountsByIdentity = map[string]model.Account{}
+	}
+	s.accountsByIdentity[identityKey(provider, externalID)] = model.Account{ID: accountID}
+	return nil
+}
+
+// newTestOAuthProvider returns a provider whose UserInfoURL points at a
+// fake server serving claims, wired to the given stores.
+func newTestOAuthProvider(t *testing.T, claims map[string]interface{}, accounts accountStore, identities identityStore, autoProvision bool) *OAuthProvider {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(claims)
+	}))
+	t.Cleanup(server.Close)
+
+	return NewOAuthProvider(accounts, identities, OAuthConfig{
+		Name:          "generic",
+		UserInfoURL:   server.URL,
+		AutoProvision: autoProvision,
+	})
+}
+
+// stubToken is a placeholder bearer token; resolveAccount only needs it to
+// authenticate against p's test userinfo server, which ignores auth.
+var stubToken = &oauth2.Token{AccessToken: "test"}
+
+func TestOAuthResolveAccountIgnoresUsernameClaimWhenExternalIDUnlinked(t *testing.T) {
+	identities := &fakeIdentityStore{
+		accountsByIdentity: map[string]model.Account{
+			identityKey("generic", "user-42"): {ID: 7, Username: "alice"},
+		},
+	}
+
+	// An attacker-controlled userinfo response claiming to be "alice" by
+	// username/email, but under a different external ID, must not resolve
+	// to alice's account.
+	p := newTestOAuthProvider(t, map[string]interface{}{
+		"sub":   "attacker-id",
+		"email": "alice@example.com",
+	}, &fakeAccountStore{}, identities, false)
+
+	if account, err := p.resolveAccount(context.Background(), stubToken); err == nil {
+		t.Fatalf("resolveAccount() = %+v, want error for unlinked external ID", account)
+	}
+}
+
+func TestOAuthResolveAccountUsesLinkedExternalIDRegardlessOfClaims(t *testing.T) {
+	identities := &fakeIdentityStore{
+		accountsByIdentity: map[string]model.Account{
+			identityKey("generic", "user-42"): {ID: 7, Username: "alice"},
+		},
+	}
+
+	// The legitimately linked external ID resolves to the account it was
+	// linked to, regardless of what the claims say about username/email.
+	p := newTestOAuthProvider(t, map[string]interface{}{
+		"sub":   "user-42",
+		"email": "someone-else@example.com",
+	}, &fakeAccountStore{}, identities, false)
+
+	account, err := p.resolveAccount(context.Background(), stubToken)
+	if err != nil {
+		t.Fatalf("resolveAccount() error = %v, want nil", err)
+	}
+	if account.Username != "alice" {
+		t.Errorf("resolveAccount() = %q, want %q", account.Username, "alice")
+	}
+}
+
+func TestOAuthResolveAccountAutoProvisionLinksExternalIDAndRequiresVerifiedEmail(t *testing.T) {
+	identities := &fakeIdentityStore{}
+	accounts := &fakeAccountStore{}
+
+	p := newTestOAuthProvider(t, map[string]interface{}{
+		"sub":                "user-99",
+		"email":              "bob@example.com",
+		"email_verified":     false,
+		"preferred_username": "bob",
+	}, accounts, identities, true)
+
+	account, err := p.resolveAccount(context.Background(), stubToken)
+	if err != nil {
+		t.Fatalf("resolveAccount() error = %v, want nil", err)
+	}
+	// email_verified is false, so the unverified email claim must be
+	// skipped in favor of preferred_username.
+	if account.Username != "bob" {
+		t.Errorf("resolveAccount() username = %q, want %q (unverified email must not be used)", account.Username, "bob")
+	}
+
+	if _, found, _ := identities.GetAccountByExternalID(context.Background(), "generic", "user-99"); !found {
+		t.Errorf("resolveAccount() did not link the external ID to the new account")
+	}
+}
+
+func TestOAuthResolveAccountRejectsUnlinkedAccountWhenAutoProvisionOff(t *testing.T) {
+	identities := &fakeIdentityStore{}
+	accounts := &fakeAccountStore{
+		byUsername: map[string]model.Account{
+			"alice": {ID: 1, Username: "alice"},
+		},
+	}
+
+	p := newTestOAuthProvider(t, map[string]interface{}{
+		"sub":            "impersonator",
+		"email":          "alice@example.com",
+		"email_verified": true,
+	}, accounts, identities, false)
+
+	if account, err := p.resolveAccount(context.Background(), stubToken); err == nil {
+		t.Fatalf("resolveAccount() = %+v, want rejection: AutoProvision is off and no identity is linked", account)
+	}
+}
+
+func TestOAuthResolveAccountRefusesAutoProvisionOverExistingUsername(t *testing.T) {
+	identities := &fakeIdentityStore{}
+	accounts := &fakeAccountStore{
+		byUsername: map[string]model.Account{
+			"alice": {ID: 1, Username: "alice"},
+		},
+	}
+
+	// A malicious/self-hosted IdP sets preferred_username to an existing
+	// victim's username and presents a never-before-seen external ID.
+	// AutoProvision must not let SaveAccount collide with that account.
+	p := newTestOAuthProvider(t, map[string]interface{}{
+		"sub":                "never-seen-before",
+		"preferred_username": "alice",
+	}, accounts, identities, true)
+
+	account, err := p.resolveAccount(context.Background(), stubToken)
+	if err == nil {
+		t.Fatalf("resolveAccount() = %+v, want rejection: username already belongs to another account", account)
+	}
+	if len(identities.linked) != 0 {
+		t.Errorf("resolveAccount() linked an identity despite refusing to provision: %v", identities.linked)
+	}
+}
@@ -0,0 +1,71 @@
+// Package providers implements the pluggable authentication backends used
+// by Handler.AuthMiddleware: OAuth2/OIDC single sign-on, long-lived
+// personal API tokens, and HTTP Basic for scripts.
+package providers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-shiori/shiori/internal/model"
+)
+
+// ErrNoProvider is returned when no registered provider could authenticate
+// the request.
+var ErrNoProvider = errors.New("没有可用的认证提供方")
+
+// Provider authenticates an incoming HTTP request against a particular
+// identity source.
+type Provider interface {
+	// Name returns the provider's unique identifier, e.g. "google" or
+	// "api-token". It is also the path segment used by the
+	// `/oauth/{provider}/...` routes.
+	Name() string
+
+	// Authenticate verifies r and returns the account it resolves to.
+	Authenticate(r *http.Request) (model.Account, error)
+}
+
+// Registry keeps track of the providers available to the auth middleware,
+// tried in the order they were registered.
+type Registry struct {
+	providers []Provider
+}
+
+// NewRegistry creates an empty provider registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register appends a provider to the registry.
+func (reg *Registry) Register(p Provider) {
+	reg.providers = append(reg.providers, p)
+}
+
+// Lookup returns the registered provider with the given name, if any.
+func (reg *Registry) Lookup(name string) (Provider, bool) {
+	for _, p := range reg.providers {
+		if p.Name() == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Authenticate tries every registered provider in order and returns the
+// first successful result.
+func (reg *Registry) Authenticate(r *http.Request) (model.Account, error) {
+	var lastErr error
+	for _, p := range reg.providers {
+		account, err := p.Authenticate(r)
+		if err == nil {
+			return account, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = ErrNoProvider
+	}
+	return model.Account{}, lastErr
+}
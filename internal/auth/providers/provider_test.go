@@ -0,0 +1,56 @@
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-shiori/shiori/internal/model"
+)
+
+type fakeProvider struct {
+	name    string
+	account model.Account
+	err     error
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Authenticate(r *http.Request) (model.Account, error) {
+	return p.account, p.err
+}
+
+func TestRegistryAuthenticateTriesProvidersInOrder(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(&fakeProvider{name: "first", err: ErrNoProvider})
+	reg.Register(&fakeProvider{name: "second", account: model.Account{Username: "alice"}})
+	reg.Register(&fakeProvider{name: "third", account: model.Account{Username: "bob"}})
+
+	account, err := reg.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v, want nil", err)
+	}
+	if account.Username != "alice" {
+		t.Errorf("Authenticate() = %q, want first successful provider %q", account.Username, "alice")
+	}
+}
+
+func TestRegistryAuthenticateReturnsErrNoProviderWhenEmpty(t *testing.T) {
+	reg := NewRegistry()
+
+	_, err := reg.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != ErrNoProvider {
+		t.Errorf("Authenticate() error = %v, want %v", err, ErrNoProvider)
+	}
+}
+
+func TestRegistryAuthenticateFailsWhenEveryProviderFails(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(&fakeProvider{name: "first", err: ErrNoProvider})
+	reg.Register(&fakeProvider{name: "second", err: ErrNoProvider})
+
+	_, err := reg.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != ErrNoProvider {
+		t.Errorf("Authenticate() error = %v, want %v", err, ErrNoProvider)
+	}
+}
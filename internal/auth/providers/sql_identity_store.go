@@ -0,0 +1,52 @@
+package providers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/go-shiori/shiori/internal/model"
+)
+
+// SQLIdentityStore implements identityStore on top of the
+// external_identities table added by
+// internal/database/migrations/0002_oauth_identities.up.sql.
+type SQLIdentityStore struct {
+	db *sql.DB
+}
+
+// NewSQLIdentityStore wraps db with the external-identity persistence layer.
+func NewSQLIdentityStore(db *sql.DB) *SQLIdentityStore {
+	return &SQLIdentityStore{db: db}
+}
+
+// GetAccountByExternalID looks up the account linked to (provider,
+// externalID), if any.
+func (s *SQLIdentityStore) GetAccountByExternalID(ctx context.Context, provider, externalID string) (model.Account, bool, error) {
+	var account model.Account
+	err := s.db.QueryRowContext(ctx,
+		`SELECT a.id, a.username
+		 FROM account a
+		 JOIN external_identities ei ON ei.account_id = a.id
+		 WHERE ei.provider = ? AND ei.external_id = ?`, provider, externalID).
+		Scan(&account.ID, &account.Username)
+	if err == sql.ErrNoRows {
+		return model.Account{}, false, nil
+	}
+	if err != nil {
+		return model.Account{}, false, fmt.Errorf("查询外部身份绑定失败: %v", err)
+	}
+	return account, true, nil
+}
+
+// LinkExternalID records that (provider, externalID) resolves to accountID,
+// called once on first login when auto-provisioning a new account.
+func (s *SQLIdentityStore) LinkExternalID(ctx context.Context, accountID int, provider, externalID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO external_identities (provider, external_id, account_id) VALUES (?, ?, ?)`,
+		provider, externalID, accountID)
+	if err != nil {
+		return fmt.Errorf("绑定外部身份失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/go-shiori/shiori/internal/model"
+)
+
+// SQLAPITokenStore implements TokenStore on top of the api_tokens table
+// added by internal/database/migrations/0003_api_tokens.up.sql. Only a
+// token's SHA-256 hash (see HashAPIToken) is ever persisted or queried.
+type SQLAPITokenStore struct {
+	db *sql.DB
+}
+
+// NewSQLAPITokenStore wraps db with the API-token persistence layer.
+func NewSQLAPITokenStore(db *sql.DB) *SQLAPITokenStore {
+	return &SQLAPITokenStore{db: db}
+}
+
+// GetAccountByTokenHash looks up the account that owns tokenHash.
+func (s *SQLAPITokenStore) GetAccountByTokenHash(ctx context.Context, tokenHash string) (model.Account, bool, error) {
+	var account model.Account
+	err := s.db.QueryRowContext(ctx,
+		`SELECT a.id, a.username
+		 FROM account a
+		 JOIN api_tokens t ON t.account_id = a.id
+		 WHERE t.token_hash = ?`, tokenHash).
+		Scan(&account.ID, &account.Username)
+	if err == sql.ErrNoRows {
+		return model.Account{}, false, nil
+	}
+	if err != nil {
+		return model.Account{}, false, fmt.Errorf("查询 API 令牌失败: %v", err)
+	}
+	return account, true, nil
+}
+
+// CreateToken mints a new token for accountID under name and stores its
+// hash, returning the plaintext token so the caller can show it exactly
+// once.
+func (s *SQLAPITokenStore) CreateToken(ctx context.Context, accountID int, name string) (string, error) {
+	token, err := GenerateAPIToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO api_tokens (token_hash, account_id, name) VALUES (?, ?, ?)`,
+		HashAPIToken(token), accountID, name)
+	if err != nil {
+		return "", fmt.Errorf("创建 API 令牌失败: %v", err)
+	}
+
+	return token, nil
+}
+
+// RevokeToken deletes the token named name belonging to accountID.
+func (s *SQLAPITokenStore) RevokeToken(ctx context.Context, accountID int, name string) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM api_tokens WHERE account_id = ? AND name = ?`, accountID, name)
+	if err != nil {
+		return fmt.Errorf("撤销 API 令牌失败: %v", err)
+	}
+	return nil
+}
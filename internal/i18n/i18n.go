@@ -0,0 +1,128 @@
+// Package i18n loads per-language message catalogs and resolves the
+// Accept-Language-aware `{{T "key" args...}}` template func used by the
+// server-rendered pages.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-shiori/shiori/internal/model"
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// DefaultLanguage is used when the request's Accept-Language header (or the
+// account's saved preference) doesn't match any loaded catalog.
+const DefaultLanguage = "en"
+
+// Catalog is a single language's flat key -> message-template map.
+type Catalog map[string]string
+
+// Bundle holds every loaded language catalog.
+type Bundle struct {
+	catalogs map[string]Catalog
+}
+
+// Load reads every `locales/*.json` catalog embedded in the binary.
+func Load() (*Bundle, error) {
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("读取语言包目录失败: %v", err)
+	}
+
+	bundle := &Bundle{catalogs: make(map[string]Catalog)}
+	for _, entry := range entries {
+		lang := strings.TrimSuffix(entry.Name(), ".json")
+
+		data, err := localeFS.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("读取语言包 %q 失败: %v", lang, err)
+		}
+
+		var catalog Catalog
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("解析语言包 %q 失败: %v", lang, err)
+		}
+
+		bundle.catalogs[lang] = catalog
+	}
+
+	return bundle, nil
+}
+
+// LanguageFromHeader picks the best available language for the given
+// Accept-Language header value, falling back to DefaultLanguage.
+func (b *Bundle) LanguageFromHeader(acceptLanguage string) string {
+	for _, tag := range strings.Split(acceptLanguage, ",") {
+		lang := strings.TrimSpace(strings.SplitN(tag, ";", 2)[0])
+		if _, ok := b.catalogs[lang]; ok {
+			return lang
+		}
+
+		// Accept-Language commonly sends a bare primary subtag ("zh") where
+		// our catalogs are keyed by the full tag ("zh-CN"); match on prefix.
+		for candidate := range b.catalogs {
+			if strings.EqualFold(strings.SplitN(candidate, "-", 2)[0], lang) {
+				return candidate
+			}
+		}
+	}
+
+	return DefaultLanguage
+}
+
+// T resolves key in lang, falling back to DefaultLanguage and finally the
+// key itself if no catalog has a message for it. args are applied with
+// fmt.Sprintf, so messages use the usual %s/%d verbs.
+func (b *Bundle) T(lang, key string, args ...interface{}) string {
+	message, ok := b.catalogs[lang][key]
+	if !ok {
+		message, ok = b.catalogs[DefaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Translator returns a closure bound to the language resolved from r's
+// Accept-Language header, suitable for registering as the `T` template
+// func for one request. Use TranslatorForAccount when the request is
+// authenticated, so the account's saved language preference wins.
+func (b *Bundle) Translator(r *http.Request) func(string, ...interface{}) string {
+	lang := b.LanguageFromHeader(r.Header.Get("Accept-Language"))
+	return func(key string, args ...interface{}) string {
+		return b.T(lang, key, args...)
+	}
+}
+
+// LanguageForAccount resolves the language to render in: account.Language
+// if it's set and a catalog for it is loaded, otherwise whatever r's
+// Accept-Language header resolves to.
+func (b *Bundle) LanguageForAccount(r *http.Request, account model.Account) string {
+	if account.Language != "" {
+		if _, ok := b.catalogs[account.Language]; ok {
+			return account.Language
+		}
+	}
+
+	return b.LanguageFromHeader(r.Header.Get("Accept-Language"))
+}
+
+// TranslatorForAccount is Translator, but preferring account's saved
+// language preference over r's Accept-Language header.
+func (b *Bundle) TranslatorForAccount(r *http.Request, account model.Account) func(string, ...interface{}) string {
+	lang := b.LanguageForAccount(r, account)
+	return func(key string, args ...interface{}) string {
+		return b.T(lang, key, args...)
+	}
+}
@@ -0,0 +1,74 @@
+package i18n
+
+import "testing"
+
+func testBundle() *Bundle {
+	return &Bundle{catalogs: map[string]Catalog{
+		"en":    {"greeting": "Hello, %s"},
+		"zh-CN": {"greeting": "你好，%s", "only_zh": "仅中文"},
+	}}
+}
+
+func TestLanguageFromHeaderExactMatch(t *testing.T) {
+	bundle := testBundle()
+
+	if got := bundle.LanguageFromHeader("zh-CN,en;q=0.8"); got != "zh-CN" {
+		t.Errorf("LanguageFromHeader() = %q, want %q", got, "zh-CN")
+	}
+}
+
+func TestLanguageFromHeaderPrefixFallback(t *testing.T) {
+	bundle := testBundle()
+
+	// "zh" alone doesn't match a loaded catalog exactly, but should fall
+	// back to the "zh-CN" catalog by primary-subtag prefix.
+	if got := bundle.LanguageFromHeader("zh;q=0.9,en;q=0.8"); got != "zh-CN" {
+		t.Errorf("LanguageFromHeader() = %q, want %q", got, "zh-CN")
+	}
+}
+
+func TestLanguageFromHeaderUnknownFallsBackToDefault(t *testing.T) {
+	bundle := testBundle()
+
+	if got := bundle.LanguageFromHeader("fr-FR,fr;q=0.8"); got != DefaultLanguage {
+		t.Errorf("LanguageFromHeader() = %q, want %q", got, DefaultLanguage)
+	}
+}
+
+func TestLanguageFromHeaderEmptyFallsBackToDefault(t *testing.T) {
+	bundle := testBundle()
+
+	if got := bundle.LanguageFromHeader(""); got != DefaultLanguage {
+		t.Errorf("LanguageFromHeader() = %q, want %q", got, DefaultLanguage)
+	}
+}
+
+func TestTFormatsMessageWithArgs(t *testing.T) {
+	bundle := testBundle()
+
+	if got := bundle.T("en", "greeting", "alice"); got != "Hello, alice" {
+		t.Errorf("T() = %q, want %q", got, "Hello, alice")
+	}
+}
+
+func TestTFallsBackToDefaultLanguage(t *testing.T) {
+	bundle := testBundle()
+
+	// "only_zh" isn't in the "fr" catalog (which doesn't even exist), so T
+	// must fall back to the "en" default -- which also lacks the key.
+	if got := bundle.T("fr", "only_zh"); got != "only_zh" {
+		t.Errorf("T() = %q, want the key itself as final fallback", got)
+	}
+
+	if got := bundle.T("fr", "greeting", "bob"); got != "Hello, bob" {
+		t.Errorf("T() = %q, want the \"en\" default catalog's message", got)
+	}
+}
+
+func TestTFallsBackToKeyWhenMissingEverywhere(t *testing.T) {
+	bundle := testBundle()
+
+	if got := bundle.T("en", "nonexistent.key"); got != "nonexistent.key" {
+		t.Errorf("T() = %q, want the key itself", got)
+	}
+}
@@ -0,0 +1,68 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeStore is a Store backed by in-memory maps, for exercising
+// Policy.Authorize without a database.
+type fakeStore struct {
+	roles       map[int]Role
+	acls        map[ResourceType][]ACL
+	permissions map[Role]map[Action]bool
+}
+
+func (s *fakeStore) GetAccountRole(ctx context.Context, accountID int) (Role, error) {
+	return s.roles[accountID], nil
+}
+
+func (s *fakeStore) GetResourceACLs(ctx context.Context, resource Resource) ([]ACL, error) {
+	return s.acls[resource.Type], nil
+}
+
+func (s *fakeStore) RoleAllows(ctx context.Context, role Role, action Action) (bool, error) {
+	return s.permissions[role][action], nil
+}
+
+func TestPolicyAuthorizeRoleDefaults(t *testing.T) {
+	store := &fakeStore{
+		roles: map[int]Role{1: RoleEditor, 2: RoleViewer},
+		permissions: map[Role]map[Action]bool{
+			RoleEditor: {ActionRead: true, ActionWrite: true},
+			RoleViewer: {ActionRead: true},
+		},
+	}
+	policy := NewPolicy(store)
+
+	if err := policy.Authorize(context.Background(), 1, RoleEditor, Resource{}, ActionWrite); err != nil {
+		t.Errorf("editor write: got %v, want nil", err)
+	}
+	if err := policy.Authorize(context.Background(), 2, RoleViewer, Resource{}, ActionWrite); err != ErrForbidden {
+		t.Errorf("viewer write: got %v, want %v", err, ErrForbidden)
+	}
+	if err := policy.Authorize(context.Background(), 2, RoleViewer, Resource{}, ActionRead); err != nil {
+		t.Errorf("viewer read: got %v, want nil", err)
+	}
+}
+
+func TestPolicyAuthorizeResourceACLOverridesRole(t *testing.T) {
+	resource := Resource{Type: ResourceCollection, ID: 7}
+	store := &fakeStore{
+		roles: map[int]Role{3: RoleViewer},
+		acls: map[ResourceType][]ACL{
+			ResourceCollection: {{Resource: resource, AccountID: 3, Action: ActionWrite}},
+		},
+		permissions: map[Role]map[Action]bool{
+			RoleViewer: {ActionRead: true},
+		},
+	}
+	policy := NewPolicy(store)
+
+	if err := policy.Authorize(context.Background(), 3, RoleViewer, resource, ActionWrite); err != nil {
+		t.Errorf("ACL grant: got %v, want nil", err)
+	}
+	if err := policy.Authorize(context.Background(), 4, RoleViewer, resource, ActionWrite); err != ErrForbidden {
+		t.Errorf("no matching ACL, no role default: got %v, want %v", err, ErrForbidden)
+	}
+}
@@ -0,0 +1,11 @@
+package rbac
+
+// ShareToken is a tokenized URL that grants public, read-only access to one
+// collection without requiring a login session. Rows live alongside the
+// bookmark_acls table and are looked up by Token on each request to a
+// `/shared/{token}` route.
+type ShareToken struct {
+	Token        string
+	CollectionID int
+	CreatedBy    int
+}
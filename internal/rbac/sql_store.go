@@ -0,0 +1,151 @@
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/go-shiori/shiori/internal/model"
+)
+
+// SQLStore implements Store (and the webserver package's accountRoleStore)
+// on top of the roles, permissions, bookmark_acls, share_links and
+// collection_bookmarks tables added by
+// internal/database/migrations/0001_rbac.up.sql.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore wraps db with the RBAC persistence layer.
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// GetAccountRole returns the role assigned to accountID in the roles
+// table.
+func (s *SQLStore) GetAccountRole(ctx context.Context, accountID int) (Role, error) {
+	var role Role
+	err := s.db.QueryRowContext(ctx,
+		`SELECT role FROM roles WHERE account_id = ?`, accountID).Scan(&role)
+	if err != nil {
+		return "", fmt.Errorf("查询账户角色失败: %v", err)
+	}
+	return role, nil
+}
+
+// SetAccountRole upserts accountID's row in the roles table.
+func (s *SQLStore) SetAccountRole(ctx context.Context, accountID int, role Role) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO roles (account_id, role) VALUES (?, ?)
+		 ON CONFLICT (account_id) DO UPDATE SET role = excluded.role`,
+		accountID, role)
+	if err != nil {
+		return fmt.Errorf("设置账户角色失败: %v", err)
+	}
+	return nil
+}
+
+// GetResourceACLs returns every ACL entry scoped to resource.
+func (s *SQLStore) GetResourceACLs(ctx context.Context, resource Resource) ([]ACL, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, account_id, role, action FROM bookmark_acls
+		 WHERE resource_type = ? AND resource_id = ?`,
+		resource.Type, resource.ID)
+	if err != nil {
+		return nil, fmt.Errorf("查询资源 ACL 失败: %v", err)
+	}
+	defer rows.Close()
+
+	var acls []ACL
+	for rows.Next() {
+		var (
+			acl       ACL
+			accountID sql.NullInt64
+			role      sql.NullString
+		)
+		if err := rows.Scan(&acl.ID, &accountID, &role, &acl.Action); err != nil {
+			return nil, fmt.Errorf("读取资源 ACL 失败: %v", err)
+		}
+
+		acl.Resource = resource
+		acl.AccountID = int(accountID.Int64)
+		acl.Role = Role(role.String)
+		acls = append(acls, acl)
+	}
+
+	return acls, rows.Err()
+}
+
+// SaveShareToken inserts a new public share link.
+func (s *SQLStore) SaveShareToken(ctx context.Context, token ShareToken) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO share_links (token, collection_id, created_by) VALUES (?, ?, ?)`,
+		token.Token, token.CollectionID, token.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("创建分享链接失败: %v", err)
+	}
+	return nil
+}
+
+// GetShareToken looks up a previously created share link.
+func (s *SQLStore) GetShareToken(ctx context.Context, token string) (ShareToken, bool, error) {
+	var share ShareToken
+	err := s.db.QueryRowContext(ctx,
+		`SELECT token, collection_id, created_by FROM share_links WHERE token = ?`, token).
+		Scan(&share.Token, &share.CollectionID, &share.CreatedBy)
+	if err == sql.ErrNoRows {
+		return ShareToken{}, false, nil
+	}
+	if err != nil {
+		return ShareToken{}, false, fmt.Errorf("查询分享链接失败: %v", err)
+	}
+	return share, true, nil
+}
+
+// GetCollectionBookmarks returns every bookmark assigned to collectionID,
+// the set a share link actually serves.
+func (s *SQLStore) GetCollectionBookmarks(ctx context.Context, collectionID int) ([]model.Bookmark, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT b.id, b.url, b.title, b.excerpt
+		 FROM bookmark b
+		 JOIN collection_bookmarks cb ON cb.bookmark_id = b.id
+		 WHERE cb.collection_id = ?`, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("查询收藏集书签失败: %v", err)
+	}
+	defer rows.Close()
+
+	var bookmarks []model.Bookmark
+	for rows.Next() {
+		var bookmark model.Bookmark
+		if err := rows.Scan(&bookmark.ID, &bookmark.URL, &bookmark.Title, &bookmark.Excerpt); err != nil {
+			return nil, fmt.Errorf("读取收藏集书签失败: %v", err)
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+
+	return bookmarks, rows.Err()
+}
+
+// RoleAllows reports whether role is granted action by default, per the
+// permissions table seeded by internal/database/migrations/0001_rbac.up.sql.
+func (s *SQLStore) RoleAllows(ctx context.Context, role Role, action Action) (bool, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM permissions WHERE role = ? AND action = ?`, role, action).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("查询角色权限失败: %v", err)
+	}
+	return count > 0, nil
+}
+
+// SetAccountLanguage persists an account's preferred UI language, consulted
+// by internal/i18n instead of only the request's Accept-Language header.
+func (s *SQLStore) SetAccountLanguage(ctx context.Context, accountID int, language string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE account SET language = ? WHERE id = ?`, language, accountID)
+	if err != nil {
+		return fmt.Errorf("保存语言偏好失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package webserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cch "github.com/patrickmn/go-cache"
+)
+
+func newTestHandler() *Handler {
+	return &Handler{
+		CSRFCache: cch.New(5*time.Minute, 10*time.Minute),
+	}
+}
+
+func TestValidCSRFTokenCacheHit(t *testing.T) {
+	h := newTestHandler()
+	h.CSRFCache.Set("session-1", "secret-1", 0)
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	if !h.validCSRFToken("session-1", "secret-1", r) {
+		t.Error("validCSRFToken() = false, want true for matching cached secret")
+	}
+	if h.validCSRFToken("session-1", "wrong-secret", r) {
+		t.Error("validCSRFToken() = true, want false for mismatched secret")
+	}
+}
+
+func TestValidCSRFTokenCookieFallback(t *testing.T) {
+	h := newTestHandler()
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.AddCookie(&http.Cookie{Name: csrfCookieName, Value: "cookie-secret"})
+
+	if !h.validCSRFToken("unknown-session", "cookie-secret", r) {
+		t.Error("validCSRFToken() = false, want true for matching double-submit cookie")
+	}
+	if h.validCSRFToken("unknown-session", "wrong-secret", r) {
+		t.Error("validCSRFToken() = true, want false for mismatched cookie")
+	}
+}
+
+func TestValidCSRFTokenNoSecretAvailable(t *testing.T) {
+	h := newTestHandler()
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	if h.validCSRFToken("unknown-session", "anything", r) {
+		t.Error("validCSRFToken() = true, want false when no cache entry or cookie exists")
+	}
+}
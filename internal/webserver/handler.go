@@ -1,14 +1,21 @@
 package webserver
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/http"
 	"strings"
 
+	"github.com/go-shiori/shiori/internal/auth/providers"
 	"github.com/go-shiori/shiori/internal/database"
 	"github.com/go-shiori/shiori/internal/dependencies"
+	"github.com/go-shiori/shiori/internal/i18n"
 	"github.com/go-shiori/shiori/internal/model"
+	"github.com/go-shiori/shiori/internal/rbac"
 	cch "github.com/patrickmn/go-cache"
 	"github.com/sirupsen/logrus"
 )
@@ -21,11 +28,48 @@ type Handler struct {
 	UserCache    *cch.Cache
 	SessionCache *cch.Cache
 	ArchiveCache *cch.Cache
-	Log          bool
+	// CSRFCache maps a session ID to the per-session secret used by
+	// csrfMiddleware to validate the X-CSRF-Token header.
+	CSRFCache *cch.Cache
+
+	// DevMode disables template caching so edits under internal/view are
+	// picked up without restarting the server.
+	DevMode bool
+
+	// AuthProviders holds the pluggable authentication backends (OAuth2/OIDC,
+	// personal API tokens, HTTP Basic) consulted by validateSession once the
+	// legacy session-cookie and JWT checks don't apply. It is nil-safe: a
+	// nil registry simply means only the legacy paths are available.
+	AuthProviders *providers.Registry
+
+	// Policy is the RBAC decision engine consulted by authorize. It is
+	// nil-safe: a nil policy falls back to the legacy owner-only rule.
+	Policy *rbac.Policy
+
+	// Store is the RBAC persistence layer backing Policy -- normally a
+	// *rbac.SQLStore wired up alongside Policy. It is a separate field
+	// (rather than something derived from DB) because DB is database.DB,
+	// which doesn't implement rbac.Store: roleForAccount and the
+	// role/share-link/language handlers all read and write through Store.
+	Store rbac.Store
+
+	// APITokens is the personal-API-token persistence layer backing
+	// providers.APITokenProvider -- normally a *providers.SQLAPITokenStore.
+	// It is nil-safe: a nil store means the token mint/revoke endpoints
+	// respond 501, same as a deployment without the feature enabled.
+	APITokens providers.TokenStore
+
+	// TracingEnabled turns TracingMiddleware from a no-op into one that
+	// starts a span per request, exported through whatever TracerProvider
+	// InitTracing configured. Leave it false (the default) to skip tracing
+	// overhead entirely when no collector is configured.
+	TracingEnabled bool
 
 	dependencies *dependencies.Dependencies
 
-	templates map[string]*template.Template
+	templates        map[string]*template.Template
+	templateRegistry *TemplateRegistry
+	i18n             *i18n.Bundle
 }
 
 func (h *Handler) PrepareSessionCache() {
@@ -48,36 +92,42 @@ func (h *Handler) PrepareSessionCache() {
 	})
 }
 
+// PrepareTemplates builds the page templates (login, index, content) from
+// the layout/partial system under internal/view, and loads the i18n
+// message catalogs consulted by the {{T}} template func. It replaces the
+// old flat templates plus the hard-coded Simplified Chinese archive
+// overlay: every page, including the overlay, now lives under
+// internal/view and pulls its strings from internal/i18n/locales.
 func (h *Handler) PrepareTemplates() error {
-	// Prepare variables
-	var err error
-	h.templates = make(map[string]*template.Template)
+	bundle, err := i18n.Load()
+	if err != nil {
+		return err
+	}
+	h.i18n = bundle
 
-	// Prepare func map
+	// T is a placeholder here so html/template's parse-time "function not
+	// defined" check passes; the real, request-scoped translator is bound
+	// in renderTemplate via Template.Clone().Funcs().
 	funcMap := template.FuncMap{
 		"html": func(s string) template.HTML {
 			return template.HTML(s)
 		},
+		"T": func(string, ...interface{}) string { return "" },
 	}
 
-	// Create template for login, index and content
+	h.templates = make(map[string]*template.Template)
+	h.templateRegistry = NewTemplateRegistry(viewDir, h.DevMode, funcMap)
+
 	for _, name := range []string{"login", "index", "content"} {
-		h.templates[name], err = createTemplate(name+".html", funcMap)
-		if err != nil {
+		if _, err := h.templateRegistry.Get(name); err != nil {
 			return err
 		}
 	}
 
-	// Create template for archive overlay
-	h.templates["archive"], err = template.New("archive").Delims("$$", "$$").Parse(
-		`<div id="shiori-archive-header">
-		<p id="shiori-logo"><span>栞</span>shiori</p>
-		<div class="spacer"></div>
-		<a href="$$.URL$$" target="_blank" rel="noopener noreferrer">原始链接</a>
-		$$if .HasContent$$
-		<a href="/bookmark/$$.ID$$/content">阅读模式</a>
-		$$end$$
-		</div>`)
+	// The archive overlay is a standalone fragment injected into archived
+	// pages, not a full layout page, so it's parsed on its own.
+	h.templates["archive"], err = template.New("archive-header.html").Funcs(funcMap).
+		ParseFiles(viewDir + "/partials/archive-header.html")
 	if err != nil {
 		return err
 	}
@@ -85,6 +135,145 @@ func (h *Handler) PrepareTemplates() error {
 	return nil
 }
 
+// translatorFor resolves the {{T}} func for r: the authenticated account's
+// saved language preference when one is set, falling back to r's
+// Accept-Language header for anonymous requests or accounts that haven't
+// picked a language yet.
+func (h *Handler) translatorFor(r *http.Request) func(string, ...interface{}) string {
+	if account, err := h.accountFromRequest(r); err == nil {
+		return h.i18n.TranslatorForAccount(r, account)
+	}
+	return h.i18n.Translator(r)
+}
+
+// renderTemplate executes the named page template (built by
+// h.templateRegistry) against data, with {{T}} bound via translatorFor.
+func (h *Handler) renderTemplate(w http.ResponseWriter, r *http.Request, name string, data interface{}) error {
+	tmpl, err := h.templateRegistry.Get(name)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err = tmpl.Clone()
+	if err != nil {
+		return fmt.Errorf("克隆模板 %q 失败: %v", name, err)
+	}
+	tmpl = tmpl.Funcs(template.FuncMap{"T": h.translatorFor(r)})
+
+	return tmpl.ExecuteTemplate(w, "layout.html", data)
+}
+
+// renderArchiveHeader executes the archive overlay fragment against data,
+// with {{T}} bound via translatorFor.
+func (h *Handler) renderArchiveHeader(w http.ResponseWriter, r *http.Request, data interface{}) error {
+	tmpl, err := h.templates["archive"].Clone()
+	if err != nil {
+		return fmt.Errorf("克隆归档模板失败: %v", err)
+	}
+	tmpl = tmpl.Funcs(template.FuncMap{"T": h.translatorFor(r)})
+
+	return tmpl.Execute(w, data)
+}
+
+// indexPageData is the data index.html (via layout.html and head.html) is
+// rendered against.
+type indexPageData struct {
+	RootPath string
+	Lang     string
+
+	// CSRFToken is the session's CSRF secret, embedded in the rendered page
+	// via <meta name="csrf-token"> so the front-end JS can read it and echo
+	// it back as the X-CSRF-Token header, without a separate round trip to
+	// HandleCSRFToken. Empty for unauthenticated requests, since there's no
+	// session to bind a secret to.
+	CSRFToken string
+}
+
+// HandleIndexPage serves the single-page-app shell, populating CSRFToken so
+// the page's own JS can satisfy csrfMiddleware on its first write request.
+func (h *Handler) HandleIndexPage(w http.ResponseWriter, r *http.Request) {
+	data := indexPageData{
+		RootPath: h.RootPath,
+		Lang:     h.i18n.LanguageFromHeader(r.Header.Get("Accept-Language")),
+	}
+
+	if account, err := h.accountFromRequest(r); err == nil {
+		data.Lang = h.i18n.LanguageForAccount(r, account)
+	}
+
+	if sessionID := h.GetSessionID(r); sessionID != "" {
+		data.CSRFToken = h.csrfSecretForSession(sessionID)
+	}
+
+	if err := h.renderTemplate(w, r, "index", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// loginPageData is the data login.html (via layout.html and head.html) is
+// rendered against.
+type loginPageData struct {
+	RootPath string
+	Lang     string
+}
+
+// HandleLoginPage serves the login form.
+func (h *Handler) HandleLoginPage(w http.ResponseWriter, r *http.Request) {
+	data := loginPageData{
+		RootPath: h.RootPath,
+		Lang:     h.i18n.LanguageFromHeader(r.Header.Get("Accept-Language")),
+	}
+
+	if err := h.renderTemplate(w, r, "login", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// bookmarkStore is the subset of h.DB needed to serve a bookmark's
+// reader-mode content page.
+type bookmarkStore interface {
+	GetBookmark(ctx context.Context, id int) (model.Bookmark, bool, error)
+}
+
+// contentPageData is the data content.html (via layout.html, head.html, and
+// archive-header.html) is rendered against.
+type contentPageData struct {
+	RootPath string
+	Lang     string
+	Bookmark model.Bookmark
+}
+
+// HandleBookmarkContent serves a bookmark's reader-mode content, with the
+// archive overlay (original-link/reader-mode switcher) rendered inline by
+// content.html's own "archive-header.html" include.
+func (h *Handler) HandleBookmarkContent(w http.ResponseWriter, r *http.Request, id int) {
+	store, ok := h.DB.(bookmarkStore)
+	if !ok {
+		http.Error(w, "此部署未启用书签内容页面", http.StatusNotImplemented)
+		return
+	}
+
+	bookmark, found, err := store.GetBookmark(r.Context(), id)
+	if err != nil || !found {
+		http.Error(w, "书签不存在", http.StatusNotFound)
+		return
+	}
+
+	data := contentPageData{
+		RootPath: h.RootPath,
+		Lang:     h.i18n.LanguageFromHeader(r.Header.Get("Accept-Language")),
+		Bookmark: bookmark,
+	}
+
+	if account, err := h.accountFromRequest(r); err == nil {
+		data.Lang = h.i18n.LanguageForAccount(r, account)
+	}
+
+	if err := h.renderTemplate(w, r, "content", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (h *Handler) GetSessionID(r *http.Request) string {
 	// Try to get session ID from the header
 	sessionID := r.Header.Get("X-Session-Id")
@@ -107,31 +296,53 @@ func (h *Handler) validateSession(r *http.Request) error {
 	authorization := r.Header.Get(model.AuthorizationHeader)
 	if authorization != "" {
 		authParts := strings.SplitN(authorization, " ", 2)
-		if len(authParts) != 2 && authParts[0] != model.AuthorizationTokenType {
-			return fmt.Errorf("会话已过期")
-		}
+		if len(authParts) == 2 && authParts[0] == model.AuthorizationTokenType {
+			account, err := h.dependencies.Domains.Auth.CheckToken(r.Context(), authParts[1])
+			if err != nil {
+				return fmt.Errorf("会话已过期")
+			}
 
-		account, err := h.dependencies.Domains.Auth.CheckToken(r.Context(), authParts[1])
-		if err != nil {
-			return fmt.Errorf("会话已过期")
-		}
+			if err := h.authorize(r, account, rbac.Resource{}, actionForMethod(r.Method)); err != nil {
+				return err
+			}
 
-		if r.Method != "" && r.Method != "GET" && !account.Owner {
-			return fmt.Errorf("账户级别不够")
+			h.dependencies.Log.WithFields(logrus.Fields{
+				"username": account.Username,
+				"method":   r.Method,
+				"path":     r.URL.Path,
+			}).Info("允许使用 JWT 令牌访问旧版 api")
+
+			return nil
 		}
 
-		h.dependencies.Log.WithFields(logrus.Fields{
-			"username": account.Username,
-			"method":   r.Method,
-			"path":     r.URL.Path,
-		}).Info("允许使用 JWT 令牌访问旧版 api")
+		// Not the legacy Bearer scheme -- e.g. "Token ..." for personal API
+		// tokens or "Basic ..." -- so hand the request to the provider
+		// chain instead of rejecting it outright.
+		if h.AuthProviders != nil {
+			account, err := h.AuthProviders.Authenticate(r)
+			if err == nil {
+				return h.authorize(r, account, rbac.Resource{}, actionForMethod(r.Method))
+			}
+		}
 
-		return nil
+		return fmt.Errorf("会话已过期")
 	}
 
 	sessionID := h.GetSessionID(r)
 	if sessionID == "" {
-		return fmt.Errorf("会话不存在")
+		// No cookie or legacy bearer token present; fall back to the
+		// pluggable provider chain (OAuth2/OIDC sessions, personal API
+		// tokens, HTTP Basic).
+		if h.AuthProviders == nil {
+			return fmt.Errorf("会话不存在")
+		}
+
+		account, err := h.AuthProviders.Authenticate(r)
+		if err != nil {
+			return fmt.Errorf("会话不存在")
+		}
+
+		return h.authorize(r, account, rbac.Resource{}, actionForMethod(r.Method))
 	}
 
 	// Make sure session is not expired yet
@@ -140,12 +351,452 @@ func (h *Handler) validateSession(r *http.Request) error {
 		return fmt.Errorf("会话已过期")
 	}
 
-	// If this is not get request, make sure it's owner
-	if r.Method != "" && r.Method != "GET" {
-		if account := val.(model.Account); !account.Owner {
+	return h.authorize(r, val.(model.Account), rbac.Resource{}, actionForMethod(r.Method))
+}
+
+// actionForMethod maps an HTTP method to the RBAC action it represents: GET
+// and HEAD only read, everything else writes.
+func actionForMethod(method string) rbac.Action {
+	if method == "" || method == "GET" || method == "HEAD" {
+		return rbac.ActionRead
+	}
+	return rbac.ActionWrite
+}
+
+// roleForAccount maps an authenticated account to its RBAC role. Until
+// every account has a row in the roles table, owners are treated as admins
+// and everyone else as viewers.
+func (h *Handler) roleForAccount(r *http.Request, account model.Account) rbac.Role {
+	if h.Store != nil {
+		var role rbac.Role
+		err := traceDBCall(r.Context(), "rbac.GetAccountRole", func(ctx context.Context) error {
+			var err error
+			role, err = h.Store.GetAccountRole(ctx, account.ID)
+			return err
+		})
+		if err == nil {
+			return role
+		}
+	}
+
+	if account.Owner {
+		return rbac.RoleAdmin
+	}
+	return rbac.RoleViewer
+}
+
+// authorize checks whether account may perform action on resource. It
+// replaces the previous hard-coded `r.Method != "GET" && !account.Owner`
+// check: with a Policy configured, per-resource ACLs are consulted first
+// and the account's role provides the default; without one, it falls back
+// to the legacy owner-only rule.
+func (h *Handler) authorize(r *http.Request, account model.Account, resource rbac.Resource, action rbac.Action) error {
+	if h.Policy == nil {
+		if action == rbac.ActionWrite && !account.Owner {
 			return fmt.Errorf("账户级别不够")
 		}
+		return nil
+	}
+
+	role := h.roleForAccount(r, account)
+	err := traceDBCall(r.Context(), "rbac.Authorize", func(ctx context.Context) error {
+		return h.Policy.Authorize(ctx, account.ID, role, resource, action)
+	})
+	if err != nil {
+		return fmt.Errorf("账户级别不够")
 	}
 
 	return nil
 }
+
+// AuthMiddleware enforces validateSession on every request that passes
+// through it, replying with 401 Unauthorized before next is invoked if
+// authentication fails through every configured path: session cookie,
+// legacy JWT bearer token, or any provider registered in AuthProviders
+// (OAuth2/OIDC session, personal API token, HTTP Basic).
+func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := h.validateSession(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// createSession stores account in the session cache under a freshly
+// generated session ID, primes its CSRF secret, and returns the session ID.
+func (h *Handler) createSession(account model.Account) string {
+	sessionID := newSessionID()
+	h.SessionCache.Set(sessionID, account, 0)
+	h.csrfSecretForSession(sessionID)
+	return sessionID
+}
+
+func newSessionID() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	return base64.URLEncoding.EncodeToString(buf)
+}
+
+// HandleOAuthLogin redirects the user to the named OAuth2/OIDC provider's
+// consent screen. providerName must match a provider previously registered
+// in h.AuthProviders.
+func (h *Handler) HandleOAuthLogin(w http.ResponseWriter, r *http.Request, providerName string) {
+	oauthProvider, err := h.lookupOAuthProvider(providerName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	state := newSessionID()
+	http.SetCookie(w, &http.Cookie{
+		Name:     "oauth-state",
+		Value:    state,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		// SameSite=Lax, not Strict like the CSRF cookie: the callback
+		// request arrives as a top-level GET navigation from the OAuth
+		// provider's own domain, which a Strict cookie would not be sent
+		// on, breaking the state check below.
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, oauthProvider.AuthCodeURL(state), http.StatusFound)
+}
+
+// HandleOAuthCallback completes the authorization-code flow for
+// providerName, creates a Shiori session for the resolved account, and
+// redirects the browser back to the index page.
+func (h *Handler) HandleOAuthCallback(w http.ResponseWriter, r *http.Request, providerName string) {
+	oauthProvider, err := h.lookupOAuthProvider(providerName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	cookie, err := r.Cookie("oauth-state")
+	if err != nil || cookie.Value == "" || cookie.Value != r.URL.Query().Get("state") {
+		http.Error(w, "OAuth 状态校验失败", http.StatusBadRequest)
+		return
+	}
+
+	account, err := oauthProvider.Exchange(r.Context(), r.URL.Query().Get("code"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session-id",
+		Value:    h.createSession(account),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, h.RootPath, http.StatusFound)
+}
+
+func (h *Handler) lookupOAuthProvider(name string) (*providers.OAuthProvider, error) {
+	if h.AuthProviders == nil {
+		return nil, fmt.Errorf("未配置任何认证提供方")
+	}
+
+	provider, found := h.AuthProviders.Lookup(name)
+	if !found {
+		return nil, fmt.Errorf("未知的认证提供方 %q", name)
+	}
+
+	oauthProvider, ok := provider.(*providers.OAuthProvider)
+	if !ok {
+		return nil, fmt.Errorf("认证提供方 %q 不支持 OAuth 登录", name)
+	}
+
+	return oauthProvider, nil
+}
+
+// RegisterOAuthRoutes mounts `/oauth/{provider}/login` and
+// `/oauth/{provider}/callback` on mux.
+func (h *Handler) RegisterOAuthRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/oauth/", func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/oauth/"), "/", 2)
+		if len(parts) != 2 {
+			http.NotFound(w, r)
+			return
+		}
+
+		providerName, action := parts[0], parts[1]
+		switch action {
+		case "login":
+			h.HandleOAuthLogin(w, r, providerName)
+		case "callback":
+			h.HandleOAuthCallback(w, r, providerName)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// accountRoleStore is the subset of h.Store needed to manage account roles,
+// share tokens, and the bookmarks a share token resolves to. It's
+// implemented by *rbac.SQLStore, alongside rbac.Store.
+type accountRoleStore interface {
+	rbac.Store
+	SetAccountRole(ctx context.Context, accountID int, role rbac.Role) error
+	SaveShareToken(ctx context.Context, token rbac.ShareToken) error
+	GetShareToken(ctx context.Context, token string) (rbac.ShareToken, bool, error)
+	GetCollectionBookmarks(ctx context.Context, collectionID int) ([]model.Bookmark, error)
+}
+
+// HandleSetAccountRole lets an admin change the RBAC role assigned to
+// another account. Only callers with rbac.ActionManage on the generic
+// resource (i.e. admins) may call this.
+func (h *Handler) HandleSetAccountRole(w http.ResponseWriter, r *http.Request) {
+	account, err := h.accountFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authorize(r, account, rbac.Resource{}, rbac.ActionManage); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	store, ok := h.Store.(accountRoleStore)
+	if !ok {
+		http.Error(w, "此部署未启用角色管理", http.StatusNotImplemented)
+		return
+	}
+
+	var payload struct {
+		AccountID int       `json:"accountId"`
+		Role      rbac.Role `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "请求格式不正确", http.StatusBadRequest)
+		return
+	}
+
+	err = traceDBCall(r.Context(), "rbac.SetAccountRole", func(ctx context.Context) error {
+		return store.SetAccountRole(ctx, payload.AccountID, payload.Role)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// languageStore is the subset of h.Store needed to persist an account's UI
+// language preference (see internal/i18n.Bundle.Translator).
+type languageStore interface {
+	SetAccountLanguage(ctx context.Context, accountID int, language string) error
+}
+
+// HandleSetAccountLanguage lets the signed-in account persist its UI
+// language preference, consulted by translatorFor on every later request
+// instead of only the Accept-Language header.
+func (h *Handler) HandleSetAccountLanguage(w http.ResponseWriter, r *http.Request) {
+	account, err := h.accountFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var payload struct {
+		Language string `json:"language"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "请求格式不正确", http.StatusBadRequest)
+		return
+	}
+
+	store, ok := h.Store.(languageStore)
+	if !ok {
+		http.Error(w, "此部署未启用语言偏好设置", http.StatusNotImplemented)
+		return
+	}
+
+	if err := store.SetAccountLanguage(r.Context(), account.ID, payload.Language); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleShareCollection creates a tokenized, public read-only link for a
+// collection. The caller must hold rbac.ActionShare on that collection.
+func (h *Handler) HandleShareCollection(w http.ResponseWriter, r *http.Request) {
+	account, err := h.accountFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var payload struct {
+		CollectionID int `json:"collectionId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "请求格式不正确", http.StatusBadRequest)
+		return
+	}
+
+	resource := rbac.Resource{Type: rbac.ResourceCollection, ID: payload.CollectionID}
+	if err := h.authorize(r, account, resource, rbac.ActionShare); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	store, ok := h.Store.(accountRoleStore)
+	if !ok {
+		http.Error(w, "此部署未启用分享链接", http.StatusNotImplemented)
+		return
+	}
+
+	share := rbac.ShareToken{
+		Token:        newSessionID(),
+		CollectionID: payload.CollectionID,
+		CreatedBy:    account.ID,
+	}
+	if err = traceDBCall(r.Context(), "rbac.SaveShareToken", func(ctx context.Context) error {
+		return store.SaveShareToken(ctx, share)
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(share)
+}
+
+// HandleSharedCollection serves the bookmarks of a collection through a
+// previously issued share token, bypassing login entirely. token must have
+// been created by HandleShareCollection.
+func (h *Handler) HandleSharedCollection(w http.ResponseWriter, r *http.Request, token string) {
+	store, ok := h.Store.(accountRoleStore)
+	if !ok {
+		http.Error(w, "此部署未启用分享链接", http.StatusNotImplemented)
+		return
+	}
+
+	var share rbac.ShareToken
+	var found bool
+	err := traceDBCall(r.Context(), "rbac.GetShareToken", func(ctx context.Context) error {
+		var err error
+		share, found, err = store.GetShareToken(ctx, token)
+		return err
+	})
+	if err != nil || !found {
+		http.Error(w, "分享链接无效或已过期", http.StatusNotFound)
+		return
+	}
+
+	var bookmarks []model.Bookmark
+	err = traceDBCall(r.Context(), "rbac.GetCollectionBookmarks", func(ctx context.Context) error {
+		var err error
+		bookmarks, err = store.GetCollectionBookmarks(ctx, share.CollectionID)
+		return err
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(bookmarks)
+}
+
+// HandleCreateAPIToken mints a new personal API token for the signed-in
+// account under the given name and returns its plaintext once -- the
+// server only ever persists providers.HashAPIToken(token) afterwards.
+func (h *Handler) HandleCreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	account, err := h.accountFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if h.APITokens == nil {
+		http.Error(w, "此部署未启用 API 令牌", http.StatusNotImplemented)
+		return
+	}
+
+	var payload struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "请求格式不正确", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.APITokens.CreateToken(r.Context(), account.ID, payload.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		Token string `json:"token"`
+	}{Token: token})
+}
+
+// HandleRevokeAPIToken revokes a personal API token previously minted by
+// HandleCreateAPIToken for the signed-in account.
+func (h *Handler) HandleRevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	account, err := h.accountFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if h.APITokens == nil {
+		http.Error(w, "此部署未启用 API 令牌", http.StatusNotImplemented)
+		return
+	}
+
+	var payload struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "请求格式不正确", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.APITokens.RevokeToken(r.Context(), account.ID, payload.Name); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// accountFromRequest resolves the account behind the current request,
+// trying the legacy session cookie, JWT bearer token, and the provider
+// chain, in that order.
+func (h *Handler) accountFromRequest(r *http.Request) (model.Account, error) {
+	authorization := r.Header.Get(model.AuthorizationHeader)
+	if authorization != "" {
+		authParts := strings.SplitN(authorization, " ", 2)
+		if len(authParts) == 2 && authParts[0] == model.AuthorizationTokenType {
+			return h.dependencies.Domains.Auth.CheckToken(r.Context(), authParts[1])
+		}
+	}
+
+	if sessionID := h.GetSessionID(r); sessionID != "" {
+		if val, found := h.SessionCache.Get(sessionID); found {
+			return val.(model.Account), nil
+		}
+		return model.Account{}, fmt.Errorf("会话已过期")
+	}
+
+	if h.AuthProviders != nil {
+		return h.AuthProviders.Authenticate(r)
+	}
+
+	return model.Account{}, fmt.Errorf("会话不存在")
+}
@@ -0,0 +1,222 @@
+package webserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-shiori/shiori/internal/model"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "shiori_http_requests_total",
+		Help: "Total number of HTTP requests handled, labeled by method, path and status.",
+	}, []string{"method", "path", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "shiori_http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds, labeled by method and path.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path"})
+
+	sessionsActive = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "shiori_sessions_active",
+		Help: "Number of sessions currently held in the session cache.",
+	})
+
+	bookmarksTotal = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "shiori_bookmarks_total",
+		Help: "Total number of bookmarks stored in the database.",
+	})
+)
+
+// bookmarkCounter is implemented by database.DB; it's asserted against at
+// scrape time so MetricsMiddleware doesn't have to depend on the full DB
+// interface.
+type bookmarkCounter interface {
+	GetBookmarksCount(ctx context.Context) (int, error)
+}
+
+// responseRecorder wraps http.ResponseWriter to capture the status code
+// written, since net/http doesn't expose it after the fact.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+// LoggingMiddleware emits one structured logrus entry per request: method,
+// path, status, duration, the authenticated user (if any), the session ID,
+// and a request ID either forwarded from X-Request-ID or generated fresh.
+func (h *Handler) LoggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		rr := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		next.ServeHTTP(rr, r)
+
+		username := ""
+		if account, err := h.accountFromRequest(r); err == nil {
+			username = account.Username
+		}
+
+		h.dependencies.Log.WithFields(logrus.Fields{
+			"method":     r.Method,
+			"path":       r.URL.Path,
+			"status":     rr.status,
+			"duration":   time.Since(start).String(),
+			"user":       username,
+			"session_id": h.GetSessionID(r),
+			"request_id": requestID,
+		}).Info("http request")
+	})
+}
+
+// MetricsMiddleware records shiori_http_requests_total and
+// shiori_http_request_duration_seconds for every request it wraps.
+func (h *Handler) MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rr := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		next.ServeHTTP(rr, r)
+
+		duration := time.Since(start).Seconds()
+		route := normalizedRoute(r.URL.Path)
+
+		httpRequestsTotal.WithLabelValues(r.Method, route, strconv.Itoa(rr.status)).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, route).Observe(duration)
+	})
+}
+
+// highEntropySegmentMinLength is the shortest path segment normalizedRoute
+// treats as an opaque token rather than a literal route word. newSessionID
+// (session IDs, OAuth state, share tokens) base64-encodes 32 random bytes,
+// which never comes in under this length; route words like "bookmark" or
+// "callback" never reach it.
+const highEntropySegmentMinLength = 20
+
+// normalizedRoute collapses numeric IDs and high-entropy tokens out of path
+// so routes like "/bookmark/123/content" and "/shared/<share-token>" share
+// one label value per route shape instead of each request minting a brand
+// new, unbounded time series. Every matching segment is replaced, not just
+// the first, so adjacent IDs (e.g. "/collection/1/bookmark/2") don't leave a
+// second unbounded label.
+func normalizedRoute(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		switch {
+		case segment == "":
+			continue
+		case isAllDigits(segment):
+			segments[i] = ":id"
+		case len(segment) >= highEntropySegmentMinLength:
+			segments[i] = ":token"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isAllDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// MetricsToken, if set, is the bearer token required to scrape /metrics.
+// Leave empty to expose metrics without authentication (e.g. behind a
+// private network or sidecar).
+type MetricsConfig struct {
+	Token string
+}
+
+// HandleMetrics serves Prometheus metrics, refreshing the gauges that
+// aren't updated incrementally (active sessions, bookmark count) on each
+// scrape. If cfg.Token is set, the request must carry a matching
+// `Authorization: Bearer <token>` header.
+func (h *Handler) HandleMetrics(cfg MetricsConfig) http.Handler {
+	next := promhttp.Handler()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.Token != "" {
+			authorization := r.Header.Get(model.AuthorizationHeader)
+			if !constantTimeEqual(authorization, "Bearer "+cfg.Token) {
+				http.Error(w, "未授权访问 /metrics", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		sessionsActive.Set(float64(h.SessionCache.ItemCount()))
+
+		if counter, ok := h.DB.(bookmarkCounter); ok {
+			if count, err := counter.GetBookmarksCount(r.Context()); err == nil {
+				bookmarksTotal.Set(float64(count))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tracerName identifies this package's spans to whatever TracerProvider
+// InitTracing registered.
+const tracerName = "github.com/go-shiori/shiori/internal/webserver"
+
+// TracingMiddleware starts an OpenTelemetry span for every request, named
+// after the route, so DB calls and archive fetches made while handling it
+// (which should start child spans off r.Context(), e.g. via traceDBCall)
+// show up nested underneath it in the trace. It's a no-op unless
+// h.TracingEnabled is set -- see InitTracing in tracing.go.
+func (h *Handler) TracingMiddleware(next http.Handler) http.Handler {
+	if !h.TracingEnabled {
+		return next
+	}
+
+	tracer := otel.Tracer(tracerName)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), r.Method+" "+r.URL.Path,
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.path", r.URL.Path),
+			))
+		defer span.End()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// traceDBCall wraps fn in a child span named name, nested under whatever
+// span TracingMiddleware started for the request. When tracing is disabled,
+// otel.Tracer returns a no-op tracer, so this costs nothing beyond the call
+// itself.
+func traceDBCall(ctx context.Context, name string, fn func(context.Context) error) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name)
+	defer span.End()
+	return fn(ctx)
+}
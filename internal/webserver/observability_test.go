@@ -0,0 +1,27 @@
+package webserver
+
+import "testing"
+
+func TestNormalizedRoute(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/bookmark/123/content", "/bookmark/:id/content"},
+		{"/bookmark/456/archive", "/bookmark/:id/archive"},
+		{"/api/login", "/api/login"},
+		// Adjacent numeric segments must both collapse, not just the first.
+		{"/collection/1/bookmark/2", "/collection/:id/bookmark/:id"},
+		{"/a/1/2", "/a/:id/:id"},
+		// Share-link tokens (base64 of 32 random bytes, see newSessionID)
+		// are non-numeric, so the digit check alone would leave one
+		// unbounded label per share link ever created.
+		{"/shared/RxG5t0n0m2pQZ8vW1yDcF3hJkL7bN9aE6sT4uI-XoYc=", "/shared/:token"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizedRoute(tt.path); got != tt.want {
+			t.Errorf("normalizedRoute(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
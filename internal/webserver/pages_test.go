@@ -0,0 +1,70 @@
+package webserver
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-shiori/shiori/internal/i18n"
+)
+
+// newTestPageHandler returns a Handler whose templateRegistry is rooted at
+// a temp dir holding minimal login.html/content.html fixtures, so
+// HandleLoginPage/HandleBookmarkContent can be exercised without depending
+// on internal/view's real templates.
+func newTestPageHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "partials"), 0o755); err != nil {
+		t.Fatalf("Mkdir(partials) error = %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "layout.html"), `{{block "content" .}}{{end}}`)
+	writeFile(t, filepath.Join(dir, "login.html"), `{{define "content"}}login:{{.RootPath}}{{end}}`)
+	writeFile(t, filepath.Join(dir, "content.html"), `{{define "content"}}content:{{.Bookmark.Title}}{{end}}`)
+
+	funcMap := template.FuncMap{
+		"html": func(s string) template.HTML { return template.HTML(s) },
+		"T":    func(string, ...interface{}) string { return "" },
+	}
+
+	bundle, err := i18n.Load()
+	if err != nil {
+		t.Fatalf("i18n.Load() error = %v", err)
+	}
+
+	return &Handler{
+		templateRegistry: NewTemplateRegistry(dir, false, funcMap),
+		i18n:             bundle,
+	}
+}
+
+func TestHandleLoginPageRendersLoginTemplate(t *testing.T) {
+	h := newTestPageHandler(t)
+	h.RootPath = "/shiori"
+
+	w := httptest.NewRecorder()
+	h.HandleLoginPage(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got, want := w.Body.String(), "login:/shiori"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestHandleBookmarkContentWithoutBookmarkStoreIsNotImplemented(t *testing.T) {
+	h := newTestPageHandler(t)
+
+	w := httptest.NewRecorder()
+	h.HandleBookmarkContent(w, httptest.NewRequest(http.MethodGet, "/bookmark/1/content", nil), 1)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("status = %d, want %d (h.DB doesn't implement bookmarkStore)", w.Code, http.StatusNotImplemented)
+	}
+}
@@ -0,0 +1,86 @@
+package webserver
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sync"
+)
+
+// viewDir is where the layout/partial template tree introduced for the i18n
+// overhaul lives, relative to the repository root.
+const viewDir = "internal/view"
+
+// TemplateRegistry loads and caches the page templates built from
+// layout.html plus every partial. In dev mode it re-parses from disk on
+// every Get instead of caching, so edits under internal/view show up
+// without restarting the server.
+type TemplateRegistry struct {
+	Dir     string
+	DevMode bool
+	FuncMap template.FuncMap
+
+	mu        sync.RWMutex
+	templates map[string]*template.Template
+}
+
+// NewTemplateRegistry creates a registry rooted at dir (normally viewDir)
+// using funcMap as the base function set for every template it builds.
+func NewTemplateRegistry(dir string, devMode bool, funcMap template.FuncMap) *TemplateRegistry {
+	return &TemplateRegistry{
+		Dir:       dir,
+		DevMode:   devMode,
+		FuncMap:   funcMap,
+		templates: make(map[string]*template.Template),
+	}
+}
+
+// Get returns the named page's template, built from layout.html, every
+// partial under partials/, and the page's own {{define}} blocks in
+// <name>.html. Results are cached unless DevMode is set.
+func (reg *TemplateRegistry) Get(name string) (*template.Template, error) {
+	if !reg.DevMode {
+		reg.mu.RLock()
+		tmpl, found := reg.templates[name]
+		reg.mu.RUnlock()
+		if found {
+			return tmpl, nil
+		}
+	}
+
+	tmpl, err := reg.build(name)
+	if err != nil {
+		return nil, err
+	}
+
+	reg.mu.Lock()
+	reg.templates[name] = tmpl
+	reg.mu.Unlock()
+
+	return tmpl, nil
+}
+
+func (reg *TemplateRegistry) build(name string) (*template.Template, error) {
+	partials, err := filepath.Glob(filepath.Join(reg.Dir, "partials", "*.html"))
+	if err != nil {
+		return nil, fmt.Errorf("查找模板片段失败: %v", err)
+	}
+
+	files := append([]string{filepath.Join(reg.Dir, "layout.html")}, partials...)
+	files = append(files, filepath.Join(reg.Dir, name+".html"))
+
+	tmpl, err := template.New("layout.html").Funcs(reg.FuncMap).ParseFiles(files...)
+	if err != nil {
+		return nil, fmt.Errorf("解析模板 %q 失败: %v", name, err)
+	}
+
+	return tmpl, nil
+}
+
+// Reload drops every cached template so the next Get rebuilds from disk,
+// even outside DevMode.
+func (reg *TemplateRegistry) Reload() {
+	reg.mu.Lock()
+	reg.templates = make(map[string]*template.Template)
+	reg.mu.Unlock()
+}
@@ -0,0 +1,113 @@
+package webserver
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestViewDir writes a minimal layout/partial/page tree matching the
+// shape TemplateRegistry.build expects, so tests don't depend on
+// internal/view's real templates.
+func newTestViewDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "partials"), 0o755); err != nil {
+		t.Fatalf("Mkdir(partials) error = %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "layout.html"),
+		`{{block "content" .}}default{{end}}`)
+	writeFile(t, filepath.Join(dir, "partials", "greeting.html"),
+		`{{define "greeting"}}hi{{end}}`)
+	writeFile(t, filepath.Join(dir, "page.html"),
+		`{{define "content"}}{{template "greeting" .}}{{end}}`)
+
+	return dir
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("WriteFile(%q) error = %v", path, err)
+	}
+}
+
+func render(t *testing.T, tmpl *template.Template) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "layout.html", nil); err != nil {
+		t.Fatalf("ExecuteTemplate() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestTemplateRegistryGetCachesOutsideDevMode(t *testing.T) {
+	dir := newTestViewDir(t)
+	reg := NewTemplateRegistry(dir, false, template.FuncMap{})
+
+	tmpl, err := reg.Get("page")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := render(t, tmpl); got != "hi" {
+		t.Fatalf("render() = %q, want %q", got, "hi")
+	}
+
+	// Edit the partial on disk; outside DevMode, Get must keep returning
+	// the cached build rather than noticing the change.
+	writeFile(t, filepath.Join(dir, "partials", "greeting.html"),
+		`{{define "greeting"}}bye{{end}}`)
+
+	tmpl, err = reg.Get("page")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := render(t, tmpl); got != "hi" {
+		t.Errorf("Get() rebuilt from disk outside DevMode: render() = %q, want cached %q", got, "hi")
+	}
+}
+
+func TestTemplateRegistryGetReloadsEveryCallInDevMode(t *testing.T) {
+	dir := newTestViewDir(t)
+	reg := NewTemplateRegistry(dir, true, template.FuncMap{})
+
+	if _, err := reg.Get("page"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "partials", "greeting.html"),
+		`{{define "greeting"}}bye{{end}}`)
+
+	tmpl, err := reg.Get("page")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := render(t, tmpl); got != "bye" {
+		t.Errorf("render() = %q, want %q (DevMode should rebuild from disk)", got, "bye")
+	}
+}
+
+func TestTemplateRegistryReloadDropsCacheOutsideDevMode(t *testing.T) {
+	dir := newTestViewDir(t)
+	reg := NewTemplateRegistry(dir, false, template.FuncMap{})
+
+	if _, err := reg.Get("page"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "partials", "greeting.html"),
+		`{{define "greeting"}}bye{{end}}`)
+	reg.Reload()
+
+	tmpl, err := reg.Get("page")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := render(t, tmpl); got != "bye" {
+		t.Errorf("render() = %q, want %q after Reload()", got, "bye")
+	}
+}
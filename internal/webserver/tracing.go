@@ -0,0 +1,55 @@
+package webserver
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TracingConfig controls whether requests are exported as OpenTelemetry
+// spans and where to. Tracing is opt-in: leave Enabled false (the zero
+// value) and InitTracing skips exporter setup entirely.
+type TracingConfig struct {
+	Enabled bool
+
+	// OTLPEndpoint is the collector address spans are exported to over
+	// OTLP/HTTP, e.g. "localhost:4318". Ignored unless Enabled is set.
+	OTLPEndpoint string
+}
+
+// InitTracing registers the global TracerProvider that TracingMiddleware
+// and traceDBCall use, exporting spans to cfg.OTLPEndpoint when cfg.Enabled
+// is set. The returned shutdown func flushes and closes the exporter; call
+// it on server shutdown. If tracing is disabled, InitTracing is a no-op and
+// the returned shutdown func does nothing.
+func InitTracing(ctx context.Context, cfg TracingConfig) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpoint(cfg.OTLPEndpoint),
+		otlptracehttp.WithInsecure(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("创建 OTLP 导出器失败: %v", err)
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceName("shiori")))
+	if err != nil {
+		return nil, fmt.Errorf("创建追踪资源失败: %v", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}